@@ -0,0 +1,49 @@
+package provider
+
+import "github.com/charmbracelet/crush/internal/llm/provider/jsonschema"
+
+// This file is scoped to the ResponseFormat -> Anthropic translation only:
+// no Anthropic provider client exists yet in this package for it to be
+// wired into. Once one lands, its request-building code should call
+// AnthropicResponseFormat the way openaiClient.send/stream call
+// applyResponseFormat.
+
+// AnthropicStructuredOutputTool is the synthetic tool name used to force
+// Anthropic into emitting JSON that matches a ResponseFormat. Anthropic has
+// no native structured-output mode, so a json_schema (or json_object)
+// format is translated into a single tool the model is forced to call,
+// with the requested schema as that tool's input_schema.
+const AnthropicStructuredOutputTool = "structured_output"
+
+// AnthropicToolChoice is the subset of Anthropic's tool-use request shape
+// needed to force a structured-output completion.
+type AnthropicToolChoice struct {
+	ToolName    string
+	InputSchema jsonschema.Definition
+}
+
+// AnthropicResponseFormat translates format into the tool-forcing shape an
+// Anthropic client should merge into its request: a tool named
+// AnthropicStructuredOutputTool plus a tool_choice forcing its use. The
+// second return value is false when format needs no translation (e.g.
+// ResponseFormatText), in which case the caller should leave its request
+// unmodified.
+func AnthropicResponseFormat(format ResponseFormat) (AnthropicToolChoice, bool) {
+	switch format.Type {
+	case ResponseFormatJSONSchema:
+		if format.JSONSchema == nil {
+			return AnthropicToolChoice{}, false
+		}
+		return AnthropicToolChoice{
+			ToolName:    AnthropicStructuredOutputTool,
+			InputSchema: format.JSONSchema.Schema,
+		}, true
+	case ResponseFormatJSONObject:
+		return AnthropicToolChoice{
+			ToolName:    AnthropicStructuredOutputTool,
+			InputSchema: jsonschema.Definition{Type: jsonschema.Object, AdditionalProperties: true},
+		}, true
+	default:
+		return AnthropicToolChoice{}, false
+	}
+}
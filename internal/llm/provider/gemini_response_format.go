@@ -0,0 +1,38 @@
+package provider
+
+import "github.com/charmbracelet/crush/internal/llm/provider/jsonschema"
+
+// This file is scoped to the ResponseFormat -> Gemini translation only: no
+// Gemini provider client exists yet in this package for it to be wired
+// into. Once one lands, its request-building code should call
+// GeminiResponseFormatParam the way openaiClient.send/stream call
+// applyResponseFormat.
+
+// GeminiResponseFormat is the subset of Gemini's GenerationConfig needed to
+// drive its native JSON mode.
+type GeminiResponseFormat struct {
+	ResponseMIMEType string
+	// ResponseSchema constrains the shape of the JSON Gemini returns. Left
+	// nil for ResponseFormatJSONObject, which asks for JSON mode without
+	// constraining its shape.
+	ResponseSchema *jsonschema.Definition
+}
+
+// GeminiResponseFormatParam translates format into Gemini's
+// GenerationConfig fields. A zero-value (or ResponseFormatText) format
+// returns the zero GeminiResponseFormat, which leaves Gemini's default
+// plain-text output untouched.
+func GeminiResponseFormatParam(format ResponseFormat) GeminiResponseFormat {
+	switch format.Type {
+	case ResponseFormatJSONObject:
+		return GeminiResponseFormat{ResponseMIMEType: "application/json"}
+	case ResponseFormatJSONSchema:
+		if format.JSONSchema == nil {
+			return GeminiResponseFormat{ResponseMIMEType: "application/json"}
+		}
+		schema := format.JSONSchema.Schema
+		return GeminiResponseFormat{ResponseMIMEType: "application/json", ResponseSchema: &schema}
+	default:
+		return GeminiResponseFormat{}
+	}
+}
@@ -0,0 +1,66 @@
+// Package jsonschema defines a small JSON Schema tree used to describe
+// structured response formats across provider clients, modeled after the
+// schema shape used by OpenAI's `json_schema` response format.
+package jsonschema
+
+import "fmt"
+
+// DataType is a JSON Schema primitive type.
+type DataType string
+
+const (
+	Object  DataType = "object"
+	Number  DataType = "number"
+	Integer DataType = "integer"
+	String  DataType = "string"
+	Array   DataType = "array"
+	Null    DataType = "null"
+	Boolean DataType = "boolean"
+)
+
+// Definition is a single node of a JSON Schema tree.
+type Definition struct {
+	Type                 DataType              `json:"type,omitempty"`
+	Description          string                `json:"description,omitempty"`
+	Enum                 []string              `json:"enum,omitempty"`
+	Properties           map[string]Definition `json:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty"`
+	Items                *Definition           `json:"items,omitempty"`
+	AdditionalProperties any                   `json:"additionalProperties,omitempty"`
+}
+
+// ValidateStrict reports whether d can be safely used with OpenAI's
+// `strict: true` structured output mode, which requires every object in
+// the tree to set `additionalProperties: false` and list all of its
+// properties as required.
+func ValidateStrict(d Definition) error {
+	if d.Type == Object {
+		if additionalProperties, ok := d.AdditionalProperties.(bool); !ok || additionalProperties {
+			return fmt.Errorf("jsonschema: strict mode requires additionalProperties: false")
+		}
+
+		required := make(map[string]bool, len(d.Required))
+		for _, name := range d.Required {
+			required[name] = true
+		}
+		for name := range d.Properties {
+			if !required[name] {
+				return fmt.Errorf("jsonschema: strict mode requires property %q to be listed in required", name)
+			}
+		}
+
+		for name, prop := range d.Properties {
+			if err := ValidateStrict(prop); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
+	if d.Items != nil {
+		if err := ValidateStrict(*d.Items); err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,369 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/crush/internal/llm/provider/sse"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// openaiClient implements ProviderClient against the OpenAI (and
+// OpenAI-compatible, e.g. Azure OpenAI) chat completions API.
+type openaiClient struct {
+	providerOptions providerClientOptions
+	client          openai.Client
+
+	// azureTokenMu guards azureToken/azureTokenExpiry, which cache the
+	// bearer token obtained from providerOptions.azureAuth.Credential.
+	azureTokenMu     sync.Mutex
+	azureToken       string
+	azureTokenExpiry time.Time
+
+	// rateLimitMu guards rateLimit, the most recently observed
+	// RateLimitInfo for this client.
+	rateLimitMu sync.Mutex
+	rateLimit   RateLimitInfo
+}
+
+// azureKeyAuth reports whether opts should authenticate with a static
+// Azure OpenAI `api-key` header rather than standard `Authorization:
+// Bearer` auth: true whenever the base URL is an Azure OpenAI endpoint
+// and Azure AD auth isn't configured (AD auth is handled per-request, in
+// requestOptions, since its token must be refreshed).
+func azureKeyAuth(opts providerClientOptions) bool {
+	return opts.azureAuth == nil && isAzureOpenAIBaseURL(opts.baseURL)
+}
+
+// NewOpenAIClient builds an openaiClient from the given options.
+func NewOpenAIClient(opts providerClientOptions) ProviderClient {
+	var clientOpts []option.RequestOption
+	switch {
+	case opts.azureAuth != nil:
+		// Azure AD (Entra ID) auth is applied per-request, once
+		// requestOptions has obtained (and possibly refreshed) a bearer
+		// token; nothing to configure on the base client.
+	case azureKeyAuth(opts):
+		// Azure OpenAI key-based auth goes in a dedicated `api-key`
+		// header. `Authorization: Bearer` is reserved for Azure AD
+		// tokens, so option.WithAPIKey (which sets Bearer) would send
+		// the key in a form Azure rejects.
+		clientOpts = append(clientOpts, option.WithHeader("api-key", opts.apiKey))
+	default:
+		clientOpts = append(clientOpts, option.WithAPIKey(opts.apiKey))
+	}
+	if opts.baseURL != "" {
+		clientOpts = append(clientOpts, option.WithBaseURL(opts.baseURL))
+	}
+	for k, v := range opts.extraHeaders {
+		clientOpts = append(clientOpts, option.WithHeader(k, v))
+	}
+
+	return &openaiClient{
+		providerOptions: opts,
+		client:          openai.NewClient(clientOpts...),
+	}
+}
+
+// isAzureOpenAIBaseURL reports whether baseURL points at an Azure OpenAI
+// (or Azure Cognitive Services) endpoint rather than standard OpenAI,
+// since Azure deployments require a different request shape.
+func isAzureOpenAIBaseURL(baseURL string) bool {
+	baseURL = strings.ToLower(baseURL)
+	if baseURL == "" {
+		return false
+	}
+	return strings.Contains(baseURL, "openai.azure.com") ||
+		strings.Contains(baseURL, "cognitiveservices.azure.com")
+}
+
+// isAzureOpenAI reports whether the client is configured against an Azure
+// OpenAI (or Azure Cognitive Services) endpoint.
+func (c *openaiClient) isAzureOpenAI() bool {
+	return isAzureOpenAIBaseURL(c.providerOptions.baseURL)
+}
+
+// convertTools translates crush's provider-agnostic ToolDefinition list
+// into the OpenAI SDK's function-tool params.
+func convertTools(tools []ToolDefinition) []openai.ChatCompletionToolParam {
+	if len(tools) == 0 {
+		return nil
+	}
+	converted := make([]openai.ChatCompletionToolParam, 0, len(tools))
+	for _, tool := range tools {
+		converted = append(converted, openai.ChatCompletionToolParam{
+			Function: openai.FunctionDefinitionParam{
+				Name:        tool.Name,
+				Description: openai.String(tool.Description),
+				Parameters:  openai.FunctionParameters(tool.Parameters),
+			},
+		})
+	}
+	return converted
+}
+
+// preparedParams builds the ChatCompletionNewParams for a request, adjusting
+// fields that differ between standard OpenAI, Azure OpenAI, and reasoning
+// (o1/o3 family) models. It returns an *UnsupportedParameterError if the
+// client is configured with a generation parameter the target model
+// rejects.
+func (c *openaiClient) preparedParams(messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolParam) (openai.ChatCompletionNewParams, error) {
+	model := c.providerOptions.model(c.providerOptions.modelType)
+
+	params := openai.ChatCompletionNewParams{
+		Model:    openai.ChatModel(model.ID),
+		Messages: messages,
+	}
+	if len(tools) > 0 {
+		params.Tools = tools
+	}
+
+	maxTokens := model.DefaultMaxTokens
+	if c.providerOptions.maxTokens > 0 {
+		maxTokens = c.providerOptions.maxTokens
+	}
+
+	// Azure OpenAI deployments and reasoning (o1/o3 family) models only
+	// accept MaxCompletionTokens; everything else uses the classic
+	// MaxTokens field.
+	if c.isAzureOpenAI() || model.CanReason {
+		params.MaxCompletionTokens = openai.Int(maxTokens)
+	} else {
+		params.MaxTokens = openai.Int(maxTokens)
+	}
+
+	if err := c.applyGenerationParams(&params, model); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// requestOptions returns the per-call option.RequestOption overrides
+// needed for this request, beyond those baked into c.client at
+// construction time. When Azure AD auth is configured it swaps the
+// `api-key` header for a refreshed `Authorization: Bearer` token and
+// rewrites the base URL to the deployment endpoint, with `api-version` set
+// as a query parameter so the SDK's own "chat/completions" path joining
+// doesn't swallow or duplicate it.
+func (c *openaiClient) requestOptions(ctx context.Context) ([]option.RequestOption, error) {
+	if c.providerOptions.azureAuth == nil {
+		return nil, nil
+	}
+	model := c.providerOptions.model(c.providerOptions.modelType)
+
+	token, err := c.azureBearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return []option.RequestOption{
+		option.WithHeaderDel("api-key"),
+		option.WithHeader("Authorization", "Bearer "+token),
+		option.WithBaseURL(c.azureDeploymentBaseURL(model.ID)),
+		option.WithQueryParam("api-version", c.azureAPIVersion()),
+	}, nil
+}
+
+func (c *openaiClient) send(ctx context.Context, messages []message.Message, tools []ToolDefinition) (*ProviderResponse, error) {
+	if err := c.waitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	format, err := c.resolveResponseFormat(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	model := c.providerOptions.model(c.providerOptions.modelType)
+	reqMessages := c.convertMessages(messages, model.CanReason)
+
+	reqOpts, err := c.requestOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// send has no event stream to publish EventRateLimit on, so it only
+	// records the observed headers for the next waitForRateLimit call.
+	reqOpts = append(reqOpts, c.rateLimitRecordOption())
+
+	params, err := c.preparedParams(reqMessages, convertTools(tools))
+	if err != nil {
+		return nil, err
+	}
+	applyResponseFormat(&params, format)
+
+	completion, err := c.client.Chat.Completions.New(ctx, params, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("openai: completion response had no choices")
+	}
+
+	return &ProviderResponse{
+		Content: completion.Choices[0].Message.Content,
+		Usage: TokenUsage{
+			InputTokens:  completion.Usage.PromptTokens,
+			OutputTokens: completion.Usage.CompletionTokens,
+		},
+	}, nil
+}
+
+// defaultMaxStreamRetries is used when providerClientOptions.maxStreamRetries
+// is unset (zero). It is deliberately small: resumption helps with a
+// transient blip, not a persistently broken connection.
+const defaultMaxStreamRetries = 2
+
+// defaultStreamRetryBackoff is used when
+// providerClientOptions.streamRetryBackoff is unset.
+const defaultStreamRetryBackoff = 500 * time.Millisecond
+
+func (c *openaiClient) stream(ctx context.Context, messages []message.Message, tools []ToolDefinition) <-chan ProviderEvent {
+	eventChan := make(chan ProviderEvent)
+
+	go func() {
+		defer close(eventChan)
+
+		if err := c.waitForRateLimit(ctx); err != nil {
+			eventChan <- ProviderEvent{Type: EventError, Error: err}
+			return
+		}
+
+		format, err := c.resolveResponseFormat(ctx)
+		if err != nil {
+			eventChan <- ProviderEvent{Type: EventError, Error: err}
+			return
+		}
+
+		model := c.providerOptions.model(c.providerOptions.modelType)
+		baseMessages := c.convertMessages(messages, model.CanReason)
+		toolParams := convertTools(tools)
+
+		maxRetries := c.providerOptions.maxStreamRetries
+		if maxRetries <= 0 {
+			maxRetries = defaultMaxStreamRetries
+		}
+		backoff := c.providerOptions.streamRetryBackoff
+		if backoff <= 0 {
+			backoff = defaultStreamRetryBackoff
+		}
+
+		var state sse.State
+
+		for attempt := 0; ; attempt++ {
+			reqOpts, err := c.requestOptions(ctx)
+			if err != nil {
+				eventChan <- ProviderEvent{Type: EventError, Error: err}
+				return
+			}
+			reqOpts = append(reqOpts, c.rateLimitOption(eventChan))
+
+			requestMessages := baseMessages
+			if state.Content() != "" {
+				// Reissue with the partial response replayed as an actual
+				// assistant turn, followed by a short nudge to continue, so
+				// the model resumes rather than restarting or commenting on
+				// its answer.
+				requestMessages = append(append([]openai.ChatCompletionMessageParamUnion{}, baseMessages...),
+					openai.AssistantMessage(state.Content()), openai.UserMessage(sse.ContinueInstruction))
+			}
+
+			params, err := c.preparedParams(requestMessages, toolParams)
+			if err != nil {
+				eventChan <- ProviderEvent{Type: EventError, Error: err}
+				return
+			}
+			applyResponseFormat(&params, format)
+
+			streamErr := c.consumeStream(ctx, params, reqOpts, eventChan, &state)
+			if streamErr == nil {
+				eventChan <- ProviderEvent{
+					Type:     EventComplete,
+					Response: &ProviderResponse{Content: state.Content()},
+				}
+				return
+			}
+
+			if !sse.IsResumable(streamErr) || attempt >= maxRetries {
+				eventChan <- ProviderEvent{Type: EventError, Error: streamErr}
+				return
+			}
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				eventChan <- ProviderEvent{Type: EventError, Error: ctx.Err()}
+				return
+			}
+		}
+	}()
+
+	return eventChan
+}
+
+// consumeStream drains a single streaming request into state and emits an
+// EventContentDelta for each non-duplicate chunk of content. It returns
+// the stream's terminal error, if any, so the caller can decide whether to
+// resume.
+func (c *openaiClient) consumeStream(ctx context.Context, params openai.ChatCompletionNewParams, reqOpts []option.RequestOption, eventChan chan<- ProviderEvent, state *sse.State) error {
+	stream := c.client.Chat.Completions.NewStreaming(ctx, params, reqOpts...)
+
+	for stream.Next() {
+		chunk := stream.Current()
+		if len(chunk.Choices) == 0 {
+			// Some gateways return a 200 with an empty choices array
+			// instead of a proper error status; skip rather than panic.
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta
+		if delta.Content == "" {
+			continue
+		}
+
+		nonDuplicate := state.Observe(sse.Chunk{ID: chunk.ID, Content: delta.Content})
+		if nonDuplicate != "" {
+			eventChan <- ProviderEvent{Type: EventContentDelta, Content: nonDuplicate}
+		}
+	}
+
+	return stream.Err()
+}
+
+// convertMessages translates crush's provider-agnostic message.Message
+// list into the OpenAI SDK's message params, prepending the client's
+// system prompt. Reasoning (o1/o3 family) models reject system messages
+// entirely, so for them the system prompt is sent as a leading user
+// message instead.
+func (c *openaiClient) convertMessages(messages []message.Message, canReason bool) []openai.ChatCompletionMessageParamUnion {
+	converted := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages)+1)
+	if c.providerOptions.systemMessage != "" {
+		if canReason {
+			converted = append(converted, openai.UserMessage(c.providerOptions.systemMessage))
+		} else {
+			converted = append(converted, openai.SystemMessage(c.providerOptions.systemMessage))
+		}
+	}
+	for _, msg := range messages {
+		var text strings.Builder
+		for _, part := range msg.Parts {
+			if tc, ok := part.(message.TextContent); ok {
+				text.WriteString(tc.Text)
+			}
+		}
+		switch msg.Role {
+		case message.User:
+			converted = append(converted, openai.UserMessage(text.String()))
+		case message.Assistant:
+			converted = append(converted, openai.AssistantMessage(text.String()))
+		}
+	}
+	return converted
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureTokenRefreshSkew is how long before expiry a cached Azure AD token
+// is proactively refreshed.
+const azureTokenRefreshSkew = 2 * time.Minute
+
+// defaultAzureAPIVersion is used when AzureAuthConfig.APIVersion is unset.
+const defaultAzureAPIVersion = "2024-06-01"
+
+// azureCognitiveServicesScope is the OAuth scope requested for managed
+// identity token acquisition.
+const azureCognitiveServicesScope = "https://cognitiveservices.azure.com/.default"
+
+// AzureTokenCredential mirrors Azure's TokenCredential interface: it
+// returns a bearer token together with its expiry so the caller can cache
+// and refresh it.
+type AzureTokenCredential func(ctx context.Context) (token string, expiresAt time.Time, err error)
+
+// AzureAuthConfig configures Azure AD (Entra ID) authentication for the
+// OpenAI provider client, as an alternative to API-key auth. Exactly one
+// of BearerToken or Credential should be set; Credential takes precedence
+// when both are present.
+type AzureAuthConfig struct {
+	// BearerToken is a static token used for every request. Suitable for
+	// short-lived testing; prefer Credential for anything long-running
+	// since it is never refreshed.
+	BearerToken string
+	// Credential, when set, is invoked (and re-invoked on expiry) to
+	// obtain a bearer token.
+	Credential AzureTokenCredential
+	// APIVersion is the Azure OpenAI `api-version` query parameter, e.g.
+	// "2024-06-01". Defaults to defaultAzureAPIVersion.
+	APIVersion string
+}
+
+// NewDefaultAzureCredential returns an AzureTokenCredential equivalent to
+// the Azure SDK's DefaultAzureCredential: it honors a pre-minted token in
+// AZURE_OPENAI_AD_TOKEN for local development, and otherwise falls back to
+// the managed-identity endpoint available on Azure compute (App Service,
+// VMs, Container Apps, ...).
+func NewDefaultAzureCredential() AzureTokenCredential {
+	return func(ctx context.Context) (string, time.Time, error) {
+		if token := os.Getenv("AZURE_OPENAI_AD_TOKEN"); token != "" {
+			return token, time.Now().Add(time.Hour), nil
+		}
+		return fetchManagedIdentityToken(ctx)
+	}
+}
+
+// managedIdentityEndpoint returns the token endpoint to call and the
+// api-version it expects: the App Service/Container Apps identity
+// endpoint (IDENTITY_ENDPOINT) when set, otherwise the default Azure
+// Instance Metadata Service endpoint. The two version their token API
+// independently, so using IMDS's endpoint with the App Service
+// api-version (or vice versa) gets rejected.
+func managedIdentityEndpoint() (endpoint, apiVersion string) {
+	if endpoint := os.Getenv("IDENTITY_ENDPOINT"); endpoint != "" {
+		return endpoint, "2019-08-01"
+	}
+	return "http://169.254.169.254/metadata/identity/oauth2/token", "2018-02-01"
+}
+
+// fetchManagedIdentityToken requests a token from the Azure Instance
+// Metadata Service (or the App Service/Container Apps identity endpoint
+// when IDENTITY_ENDPOINT is set).
+func fetchManagedIdentityToken(ctx context.Context) (string, time.Time, error) {
+	endpoint, apiVersion := managedIdentityEndpoint()
+
+	reqURL := fmt.Sprintf("%s?api-version=%s&resource=%s", endpoint, apiVersion, url.QueryEscape(azureCognitiveServicesScope))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("azure managed identity: build request: %w", err)
+	}
+	if header := os.Getenv("IDENTITY_HEADER"); header != "" {
+		req.Header.Set("X-IDENTITY-HEADER", header)
+	} else {
+		req.Header.Set("Metadata", "true")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("azure managed identity: request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("azure managed identity: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresOn   string `json:"expires_on"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", time.Time{}, fmt.Errorf("azure managed identity: decode response: %w", err)
+	}
+
+	expiresOn, err := strconv.ParseInt(body.ExpiresOn, 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("azure managed identity: parse expires_on: %w", err)
+	}
+
+	return body.AccessToken, time.Unix(expiresOn, 0), nil
+}
+
+// azureBearerToken returns a valid bearer token for the configured
+// AzureAuthConfig, refreshing it via Credential when the cached token is
+// missing or within azureTokenRefreshSkew of expiring.
+func (c *openaiClient) azureBearerToken(ctx context.Context) (string, error) {
+	auth := c.providerOptions.azureAuth
+	if auth == nil {
+		return "", fmt.Errorf("azure auth: not configured")
+	}
+	if auth.Credential == nil {
+		if auth.BearerToken == "" {
+			return "", fmt.Errorf("azure auth: no bearer token or credential configured")
+		}
+		return auth.BearerToken, nil
+	}
+
+	c.azureTokenMu.Lock()
+	defer c.azureTokenMu.Unlock()
+
+	if c.azureToken != "" && time.Until(c.azureTokenExpiry) > azureTokenRefreshSkew {
+		return c.azureToken, nil
+	}
+
+	token, expiresAt, err := auth.Credential(ctx)
+	if err != nil {
+		return "", fmt.Errorf("azure auth: refresh token: %w", err)
+	}
+	c.azureToken = token
+	c.azureTokenExpiry = expiresAt
+	return token, nil
+}
+
+// azureAPIVersion returns the configured api-version, falling back to
+// defaultAzureAPIVersion.
+func (c *openaiClient) azureAPIVersion() string {
+	if c.providerOptions.azureAuth != nil && c.providerOptions.azureAuth.APIVersion != "" {
+		return c.providerOptions.azureAuth.APIVersion
+	}
+	return defaultAzureAPIVersion
+}
+
+// azureDeploymentBaseURL composes the Azure OpenAI deployment base URL for
+// the given model/deployment ID: `<baseURL>/openai/deployments/<id>`. The
+// openai-go client appends its own relative endpoint (e.g.
+// "chat/completions") to whatever base URL it's given, so this must stop
+// short of that segment — and the `api-version` query parameter is
+// supplied separately via option.WithQueryParam, since a query string
+// baked in here would be dropped (or duplicated, along with the path) by
+// the client's URL joining.
+func (c *openaiClient) azureDeploymentBaseURL(modelID string) string {
+	base := strings.TrimRight(c.providerOptions.baseURL, "/")
+	return fmt.Sprintf("%s/openai/deployments/%s", base, modelID)
+}
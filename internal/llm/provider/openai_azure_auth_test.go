@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+)
+
+func TestAzureKeyAuth(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseURL   string
+		azureAuth *AzureAuthConfig
+		expected  bool
+	}{
+		{
+			name:     "Azure OpenAI host, no AD auth configured",
+			baseURL:  "https://myresource.openai.azure.com",
+			expected: true,
+		},
+		{
+			name:     "Azure Cognitive Services host, no AD auth configured",
+			baseURL:  "https://my-custom-name.cognitiveservices.azure.com/",
+			expected: true,
+		},
+		{
+			name:      "Azure OpenAI host with AD auth configured",
+			baseURL:   "https://myresource.openai.azure.com",
+			azureAuth: &AzureAuthConfig{BearerToken: "token"},
+			expected:  false,
+		},
+		{
+			name:     "standard OpenAI host",
+			baseURL:  "https://api.openai.com/v1",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := azureKeyAuth(providerClientOptions{baseURL: tt.baseURL, azureAuth: tt.azureAuth})
+			if got != tt.expected {
+				t.Errorf("azureKeyAuth() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManagedIdentityEndpoint(t *testing.T) {
+	t.Run("default IMDS endpoint", func(t *testing.T) {
+		t.Setenv("IDENTITY_ENDPOINT", "")
+
+		endpoint, apiVersion := managedIdentityEndpoint()
+		if endpoint != "http://169.254.169.254/metadata/identity/oauth2/token" {
+			t.Errorf("endpoint = %q, want the default IMDS endpoint", endpoint)
+		}
+		if apiVersion != "2018-02-01" {
+			t.Errorf("apiVersion = %q, want %q for the default IMDS endpoint", apiVersion, "2018-02-01")
+		}
+	})
+
+	t.Run("App Service / Container Apps identity endpoint", func(t *testing.T) {
+		t.Setenv("IDENTITY_ENDPOINT", "http://localhost:12345/msi/token")
+
+		endpoint, apiVersion := managedIdentityEndpoint()
+		if endpoint != "http://localhost:12345/msi/token" {
+			t.Errorf("endpoint = %q, want %q", endpoint, "http://localhost:12345/msi/token")
+		}
+		if apiVersion != "2019-08-01" {
+			t.Errorf("apiVersion = %q, want %q for the App Service identity endpoint", apiVersion, "2019-08-01")
+		}
+	})
+}
+
+func TestAzureBearerToken(t *testing.T) {
+	t.Run("static bearer token", func(t *testing.T) {
+		client := &openaiClient{
+			providerOptions: providerClientOptions{
+				azureAuth: &AzureAuthConfig{BearerToken: "static-token"},
+			},
+		}
+
+		token, err := client.azureBearerToken(t.Context())
+		if err != nil {
+			t.Fatalf("azureBearerToken() error = %v", err)
+		}
+		if token != "static-token" {
+			t.Errorf("azureBearerToken() = %q, want %q", token, "static-token")
+		}
+	})
+
+	t.Run("credential is refreshed and cached", func(t *testing.T) {
+		calls := 0
+		client := &openaiClient{
+			providerOptions: providerClientOptions{
+				azureAuth: &AzureAuthConfig{
+					Credential: func(ctx context.Context) (string, time.Time, error) {
+						calls++
+						return "fresh-token", time.Now().Add(time.Hour), nil
+					},
+				},
+			},
+		}
+
+		for range 2 {
+			token, err := client.azureBearerToken(t.Context())
+			if err != nil {
+				t.Fatalf("azureBearerToken() error = %v", err)
+			}
+			if token != "fresh-token" {
+				t.Errorf("azureBearerToken() = %q, want %q", token, "fresh-token")
+			}
+		}
+		if calls != 1 {
+			t.Errorf("Credential called %d times, want 1 (token should be cached)", calls)
+		}
+	})
+
+	t.Run("credential is refreshed once expired", func(t *testing.T) {
+		client := &openaiClient{
+			providerOptions: providerClientOptions{
+				azureAuth: &AzureAuthConfig{
+					Credential: func(ctx context.Context) (string, time.Time, error) {
+						return "expired-token", time.Now().Add(-time.Minute), nil
+					},
+				},
+			},
+		}
+
+		if _, err := client.azureBearerToken(t.Context()); err != nil {
+			t.Fatalf("azureBearerToken() error = %v", err)
+		}
+		// Cached token is already within the refresh skew, so a second call
+		// must invoke the credential again rather than reuse it.
+		client.providerOptions.azureAuth.Credential = func(ctx context.Context) (string, time.Time, error) {
+			return "renewed-token", time.Now().Add(time.Hour), nil
+		}
+		token, err := client.azureBearerToken(t.Context())
+		if err != nil {
+			t.Fatalf("azureBearerToken() error = %v", err)
+		}
+		if token != "renewed-token" {
+			t.Errorf("azureBearerToken() = %q, want %q", token, "renewed-token")
+		}
+	})
+
+	t.Run("credential error is surfaced", func(t *testing.T) {
+		client := &openaiClient{
+			providerOptions: providerClientOptions{
+				azureAuth: &AzureAuthConfig{
+					Credential: func(ctx context.Context) (string, time.Time, error) {
+						return "", time.Time{}, errors.New("boom")
+					},
+				},
+			},
+		}
+
+		if _, err := client.azureBearerToken(t.Context()); err == nil {
+			t.Error("azureBearerToken() error = nil, want error")
+		}
+	})
+
+	t.Run("unconfigured auth returns error", func(t *testing.T) {
+		client := &openaiClient{providerOptions: providerClientOptions{azureAuth: &AzureAuthConfig{}}}
+
+		if _, err := client.azureBearerToken(t.Context()); err == nil {
+			t.Error("azureBearerToken() error = nil, want error")
+		}
+	})
+}
+
+func TestAzureDeploymentBaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		modelID  string
+		expected string
+	}{
+		{
+			name:     "openai.azure.com host",
+			baseURL:  "https://myresource.openai.azure.com",
+			modelID:  "gpt-4o",
+			expected: "https://myresource.openai.azure.com/openai/deployments/gpt-4o",
+		},
+		{
+			name:     "cognitiveservices.azure.com host",
+			baseURL:  "https://my-custom-name.cognitiveservices.azure.com/",
+			modelID:  "gpt-4o-mini",
+			expected: "https://my-custom-name.cognitiveservices.azure.com/openai/deployments/gpt-4o-mini",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &openaiClient{
+				providerOptions: providerClientOptions{baseURL: tt.baseURL},
+			}
+
+			got := client.azureDeploymentBaseURL(tt.modelID)
+			if got != tt.expected {
+				t.Errorf("azureDeploymentBaseURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRequestOptionsSkippedWithoutAzureAuth(t *testing.T) {
+	client := &openaiClient{
+		providerOptions: providerClientOptions{
+			baseURL:   "https://api.openai.com/v1",
+			modelType: config.SelectedModelTypeLarge,
+			model: func(config.SelectedModelType) catwalk.Model {
+				return catwalk.Model{ID: "gpt-4o"}
+			},
+		},
+	}
+
+	opts, err := client.requestOptions(t.Context())
+	if err != nil {
+		t.Fatalf("requestOptions() error = %v", err)
+	}
+	if opts != nil {
+		t.Errorf("requestOptions() = %v, want nil when Azure AD auth is not configured", opts)
+	}
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/message"
+)
+
+// newAzureADTestClient builds a client configured for Azure AD auth against
+// server, the way NewOpenAIClient would construct one.
+func newAzureADTestClient(server *httptest.Server) *openaiClient {
+	client := NewOpenAIClient(providerClientOptions{
+		baseURL:   server.URL,
+		modelType: config.SelectedModelTypeLarge,
+		azureAuth: &AzureAuthConfig{BearerToken: "test-token", APIVersion: "2024-06-01"},
+		model: func(config.SelectedModelType) catwalk.Model {
+			return catwalk.Model{ID: "gpt-4o", Name: "gpt-4o"}
+		},
+	})
+	return client.(*openaiClient)
+}
+
+const wantAzureDeploymentPath = "/openai/deployments/gpt-4o/chat/completions"
+const wantAzureAPIVersionQuery = "api-version=2024-06-01"
+
+// TestAzureADRequestPathAndQuery drives send and stream against a real
+// httptest server with Azure AD auth configured, and asserts the path and
+// query the server actually receives: option.WithBaseURL's deployment
+// endpoint plus `api-version` carried as its own query parameter rather
+// than baked into a base URL the SDK's own path-joining would mangle.
+func TestAzureADRequestPathAndQuery(t *testing.T) {
+	t.Run("send", func(t *testing.T) {
+		var gotPath, gotQuery string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			gotQuery = r.URL.RawQuery
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			completion := map[string]any{
+				"id":      "chat-completion-test",
+				"object":  "chat.completion",
+				"created": time.Now().Unix(),
+				"model":   "gpt-4o",
+				"choices": []any{
+					map[string]any{
+						"index":         0,
+						"finish_reason": "stop",
+						"message":       map[string]any{"role": "assistant", "content": "hi"},
+					},
+				},
+			}
+			data, _ := json.Marshal(completion)
+			w.Write(data)
+		}))
+		defer server.Close()
+
+		client := newAzureADTestClient(server)
+		messages := []message.Message{
+			{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Hello"}}},
+		}
+
+		if _, err := client.send(t.Context(), messages, nil); err != nil {
+			t.Fatalf("send() error = %v", err)
+		}
+		if gotPath != wantAzureDeploymentPath {
+			t.Errorf("request path = %q, want %q", gotPath, wantAzureDeploymentPath)
+		}
+		if gotQuery != wantAzureAPIVersionQuery {
+			t.Errorf("request query = %q, want %q", gotQuery, wantAzureAPIVersionQuery)
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		var gotPath, gotQuery string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			gotQuery = r.URL.RawQuery
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			chunk := map[string]any{
+				"id":      "chat-completion-test",
+				"object":  "chat.completion.chunk",
+				"created": time.Now().Unix(),
+				"model":   "gpt-4o",
+				"choices": []any{
+					map[string]any{
+						"index": 0,
+						"delta": map[string]any{"content": "hi"},
+					},
+				},
+			}
+			data, _ := json.Marshal(chunk)
+			w.Write([]byte("data: " + string(data) + "\n\n"))
+			w.Write([]byte("data: [DONE]\n\n"))
+		}))
+		defer server.Close()
+
+		client := newAzureADTestClient(server)
+		messages := []message.Message{
+			{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Hello"}}},
+		}
+
+		for event := range client.stream(t.Context(), messages, nil) {
+			if event.Type == EventError || event.Type == EventComplete {
+				break
+			}
+		}
+
+		if gotPath != wantAzureDeploymentPath {
+			t.Errorf("request path = %q, want %q", gotPath, wantAzureDeploymentPath)
+		}
+		if gotQuery != wantAzureAPIVersionQuery {
+			t.Errorf("request query = %q, want %q", gotQuery, wantAzureAPIVersionQuery)
+		}
+	})
+}
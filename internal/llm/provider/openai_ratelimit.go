@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openai/openai-go/option"
+)
+
+// defaultRateLimitTokenThreshold is used when
+// providerClientOptions.rateLimitTokenThreshold is unset.
+const defaultRateLimitTokenThreshold = 1000
+
+// RateLimitInfo captures the `x-ratelimit-*` headers OpenAI returns on
+// every chat completions response, plus any `Retry-After` seen on a 429.
+type RateLimitInfo struct {
+	LimitRequests     int64
+	LimitTokens       int64
+	RemainingRequests int64
+	RemainingTokens   int64
+	ResetRequests     time.Duration
+	ResetTokens       time.Duration
+	RetryAfter        time.Duration
+	// ObservedAt is when this info was captured, so callers can account
+	// for time already elapsed when deciding how long to wait.
+	ObservedAt time.Time
+}
+
+// parseRateLimitHeaders extracts RateLimitInfo from a response's headers.
+// Missing or malformed headers simply leave the corresponding field zero.
+func parseRateLimitHeaders(h http.Header) RateLimitInfo {
+	return RateLimitInfo{
+		LimitRequests:     parseRateLimitInt(h.Get("x-ratelimit-limit-requests")),
+		LimitTokens:       parseRateLimitInt(h.Get("x-ratelimit-limit-tokens")),
+		RemainingRequests: parseRateLimitInt(h.Get("x-ratelimit-remaining-requests")),
+		RemainingTokens:   parseRateLimitInt(h.Get("x-ratelimit-remaining-tokens")),
+		ResetRequests:     parseRateLimitDuration(h.Get("x-ratelimit-reset-requests")),
+		ResetTokens:       parseRateLimitDuration(h.Get("x-ratelimit-reset-tokens")),
+		RetryAfter:        parseRetryAfter(h.Get("Retry-After")),
+		ObservedAt:        time.Now(),
+	}
+}
+
+func parseRateLimitInt(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// parseRateLimitDuration parses the Go-style duration strings (e.g. "1s",
+// "6m0s") OpenAI sends in its reset headers.
+func parseRateLimitDuration(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// parseRetryAfter parses the HTTP Retry-After header as a number of
+// seconds. OpenAI does not send the HTTP-date form, so that's the only
+// form handled here.
+func parseRetryAfter(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	secs, err := strconv.Atoi(s)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// recordRateLimit extracts RateLimitInfo from resp and caches it on c,
+// falling back to the request-reset window for Retry-After on a 429 that
+// omits it.
+func (c *openaiClient) recordRateLimit(resp *http.Response) RateLimitInfo {
+	info := parseRateLimitHeaders(resp.Header)
+	if resp.StatusCode == http.StatusTooManyRequests && info.RetryAfter == 0 {
+		// Some gateways omit Retry-After on 429s; fall back to the
+		// advertised request-reset window rather than hammering them.
+		info.RetryAfter = info.ResetRequests
+	}
+
+	c.rateLimitMu.Lock()
+	c.rateLimit = info
+	c.rateLimitMu.Unlock()
+
+	return info
+}
+
+// rateLimitOption returns a request option that records the rate-limit
+// headers of every response on c, and emits an EventRateLimit on
+// eventChan so callers (and the UI) can surface it. Use
+// rateLimitRecordOption instead for calls with no event stream to
+// publish to, such as send.
+func (c *openaiClient) rateLimitOption(eventChan chan<- ProviderEvent) option.RequestOption {
+	return option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		resp, err := next(req)
+		if resp == nil {
+			return resp, err
+		}
+
+		info := c.recordRateLimit(resp)
+		eventChan <- ProviderEvent{Type: EventRateLimit, RateLimit: &info}
+
+		return resp, err
+	})
+}
+
+// rateLimitRecordOption returns a request option that records the
+// rate-limit headers of every response on c without publishing an event,
+// for non-streaming calls that have no event channel to publish to.
+func (c *openaiClient) rateLimitRecordOption() option.RequestOption {
+	return option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		resp, err := next(req)
+		if resp == nil {
+			return resp, err
+		}
+		c.recordRateLimit(resp)
+		return resp, err
+	})
+}
+
+// waitForRateLimit blocks until it is safe to issue the next request,
+// based on the most recently observed RateLimitInfo: it honors an
+// explicit Retry-After, and otherwise waits out the token-reset window
+// once remaining tokens drop below rateLimitTokenThreshold.
+func (c *openaiClient) waitForRateLimit(ctx context.Context) error {
+	c.rateLimitMu.Lock()
+	info := c.rateLimit
+	c.rateLimitMu.Unlock()
+
+	threshold := c.providerOptions.rateLimitTokenThreshold
+	if threshold <= 0 {
+		threshold = defaultRateLimitTokenThreshold
+	}
+
+	var wait time.Duration
+	switch {
+	case info.RetryAfter > 0:
+		wait = info.RetryAfter
+	case info.LimitTokens > 0 && info.RemainingTokens < threshold:
+		wait = info.ResetTokens
+	default:
+		return nil
+	}
+
+	wait -= time.Since(info.ObservedAt)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,282 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+func newRateLimitTestClient(server *httptest.Server) *openaiClient {
+	return &openaiClient{
+		providerOptions: providerClientOptions{
+			modelType:     config.SelectedModelTypeLarge,
+			apiKey:        "test-key",
+			systemMessage: "test",
+			model: func(config.SelectedModelType) catwalk.Model {
+				return catwalk.Model{ID: "test-model", Name: "test-model"}
+			},
+		},
+		client: openai.NewClient(
+			option.WithAPIKey("test-key"),
+			option.WithBaseURL(server.URL),
+		),
+	}
+}
+
+func writeChunkWithHeaders(w http.ResponseWriter, headers map[string]string) {
+	for k, v := range headers {
+		w.Header().Set(k, v)
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+
+	chunk := map[string]any{
+		"id":      "chat-completion-test",
+		"object":  "chat.completion.chunk",
+		"created": time.Now().Unix(),
+		"model":   "test-model",
+		"choices": []any{
+			map[string]any{
+				"index": 0,
+				"delta": map[string]any{"content": "hi"},
+			},
+		},
+	}
+	data, _ := json.Marshal(chunk)
+	w.Write([]byte("data: " + string(data) + "\n\n"))
+	w.Write([]byte("data: [DONE]\n\n"))
+}
+
+func TestRateLimitHeaderParsing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeChunkWithHeaders(w, map[string]string{
+			"x-ratelimit-limit-requests":     "100",
+			"x-ratelimit-limit-tokens":       "40000",
+			"x-ratelimit-remaining-requests": "99",
+			"x-ratelimit-remaining-tokens":   "39500",
+			"x-ratelimit-reset-requests":     "600ms",
+			"x-ratelimit-reset-tokens":       "1s",
+		})
+	}))
+	defer server.Close()
+
+	client := newRateLimitTestClient(server)
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Hello"}}},
+	}
+
+	var rateLimitEvent *RateLimitInfo
+	for event := range client.stream(t.Context(), messages, nil) {
+		if event.Type == EventRateLimit {
+			rateLimitEvent = event.RateLimit
+		}
+		if event.Type == EventError || event.Type == EventComplete {
+			break
+		}
+	}
+
+	if rateLimitEvent == nil {
+		t.Fatal("expected an EventRateLimit event, got none")
+	}
+	if rateLimitEvent.LimitRequests != 100 || rateLimitEvent.LimitTokens != 40000 {
+		t.Errorf("unexpected limits: %+v", rateLimitEvent)
+	}
+	if rateLimitEvent.RemainingRequests != 99 || rateLimitEvent.RemainingTokens != 39500 {
+		t.Errorf("unexpected remaining: %+v", rateLimitEvent)
+	}
+	if rateLimitEvent.ResetRequests != 600*time.Millisecond || rateLimitEvent.ResetTokens != time.Second {
+		t.Errorf("unexpected reset windows: %+v", rateLimitEvent)
+	}
+
+	client.rateLimitMu.Lock()
+	cached := client.rateLimit
+	client.rateLimitMu.Unlock()
+	if cached.LimitTokens != 40000 {
+		t.Errorf("rate limit info was not cached on the client: %+v", cached)
+	}
+}
+
+func TestWaitForRateLimitBacksOffUntilReset(t *testing.T) {
+	const resetWindow = 150 * time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeChunkWithHeaders(w, map[string]string{
+			"x-ratelimit-limit-tokens":     "1000",
+			"x-ratelimit-remaining-tokens": "10",
+			"x-ratelimit-reset-tokens":     resetWindow.String(),
+		})
+	}))
+	defer server.Close()
+
+	client := newRateLimitTestClient(server)
+	client.providerOptions.rateLimitTokenThreshold = 100
+
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Hello"}}},
+	}
+
+	// First call observes the low remaining-tokens headers.
+	for event := range client.stream(t.Context(), messages, nil) {
+		if event.Type == EventError || event.Type == EventComplete {
+			break
+		}
+	}
+
+	start := time.Now()
+	// Second call must wait out the advertised reset window before its
+	// request reaches the server.
+	for event := range client.stream(t.Context(), messages, nil) {
+		if event.Type == EventError || event.Type == EventComplete {
+			break
+		}
+	}
+	if elapsed := time.Since(start); elapsed < resetWindow {
+		t.Errorf("second call only waited %v, want at least %v", elapsed, resetWindow)
+	}
+}
+
+func TestSendAndStreamPassToolsThrough(t *testing.T) {
+	tools := []ToolDefinition{
+		{Name: "get_weather", Description: "Get the weather", Parameters: map[string]any{"type": "object"}},
+	}
+
+	t.Run("send", func(t *testing.T) {
+		var gotBody string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf := make([]byte, 4096)
+			n, _ := r.Body.Read(buf)
+			gotBody = string(buf[:n])
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			completion := map[string]any{
+				"id":      "chat-completion-test",
+				"object":  "chat.completion",
+				"created": time.Now().Unix(),
+				"model":   "test-model",
+				"choices": []any{
+					map[string]any{
+						"index":         0,
+						"finish_reason": "stop",
+						"message":       map[string]any{"role": "assistant", "content": "hi"},
+					},
+				},
+			}
+			data, _ := json.Marshal(completion)
+			w.Write(data)
+		}))
+		defer server.Close()
+
+		client := newRateLimitTestClient(server)
+		messages := []message.Message{
+			{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "What's the weather?"}}},
+		}
+
+		if _, err := client.send(t.Context(), messages, tools); err != nil {
+			t.Fatalf("send() error = %v", err)
+		}
+		if !strings.Contains(gotBody, "get_weather") {
+			t.Errorf("request body did not include the registered tool: %s", gotBody)
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		var gotBody string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf := make([]byte, 4096)
+			n, _ := r.Body.Read(buf)
+			gotBody = string(buf[:n])
+			writeChunkWithHeaders(w, nil)
+		}))
+		defer server.Close()
+
+		client := newRateLimitTestClient(server)
+		messages := []message.Message{
+			{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "What's the weather?"}}},
+		}
+
+		for event := range client.stream(t.Context(), messages, tools) {
+			if event.Type == EventError || event.Type == EventComplete {
+				break
+			}
+		}
+		if !strings.Contains(gotBody, "get_weather") {
+			t.Errorf("request body did not include the registered tool: %s", gotBody)
+		}
+	})
+}
+
+func TestSendBacksOffAfterRetryAfter(t *testing.T) {
+	const retryAfterSeconds = 1
+	const retryAfter = retryAfterSeconds * time.Second
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		completion := map[string]any{
+			"id":      "chat-completion-test",
+			"object":  "chat.completion",
+			"created": time.Now().Unix(),
+			"model":   "test-model",
+			"choices": []any{
+				map[string]any{
+					"index":         0,
+					"finish_reason": "stop",
+					"message":       map[string]any{"role": "assistant", "content": "hi"},
+				},
+			},
+		}
+		data, _ := json.Marshal(completion)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client := newRateLimitTestClient(server)
+	// The SDK retries a 429 on its own by default; turn that off so the 429
+	// reaches send.
+	client.client = openai.NewClient(
+		option.WithAPIKey("test-key"),
+		option.WithBaseURL(server.URL),
+		option.WithMaxRetries(0),
+	)
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Hello"}}},
+	}
+
+	// First call gets the 429 and records its Retry-After; send surfaces
+	// the error rather than retrying itself (retries are the caller's
+	// responsibility, same as for a successful response).
+	if _, err := client.send(t.Context(), messages, nil); err == nil {
+		t.Fatal("send() error = nil, want an error from the 429 response")
+	}
+
+	start := time.Now()
+	if _, err := client.send(t.Context(), messages, nil); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < retryAfter {
+		t.Errorf("second call only waited %v, want at least %v (Retry-After)", elapsed, retryAfter)
+	}
+	if calls != 2 {
+		t.Errorf("server received %d calls, want 2", calls)
+	}
+}
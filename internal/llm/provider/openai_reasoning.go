@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/openai/openai-go"
+)
+
+// applyGenerationParams sets the configured sampling/generation parameters
+// on params. For reasoning (o1/o3 family) models, the OpenAI API rejects
+// Temperature, TopP, PresencePenalty, FrequencyPenalty, LogProbs, Stop,
+// and any N other than 1, so those are reported as UnsupportedParameterError
+// instead of being sent; ReasoningEffort is the one parameter reasoning
+// models accept in their place.
+func (c *openaiClient) applyGenerationParams(params *openai.ChatCompletionNewParams, model catwalk.Model) error {
+	opts := c.providerOptions
+
+	if !model.CanReason {
+		if opts.temperature != nil {
+			params.Temperature = openai.Float(*opts.temperature)
+		}
+		if opts.topP != nil {
+			params.TopP = openai.Float(*opts.topP)
+		}
+		if opts.presencePenalty != nil {
+			params.PresencePenalty = openai.Float(*opts.presencePenalty)
+		}
+		if opts.frequencyPenalty != nil {
+			params.FrequencyPenalty = openai.Float(*opts.frequencyPenalty)
+		}
+		if opts.logProbs != nil {
+			params.LogProbs = openai.Bool(*opts.logProbs)
+		}
+		if len(opts.stop) > 0 {
+			params.Stop = opts.stop
+		}
+		if opts.n != nil {
+			params.N = openai.Int(*opts.n)
+		}
+		return nil
+	}
+
+	if opts.temperature != nil {
+		return &UnsupportedParameterError{Parameter: "temperature", Model: model.ID}
+	}
+	if opts.topP != nil {
+		return &UnsupportedParameterError{Parameter: "top_p", Model: model.ID}
+	}
+	if opts.presencePenalty != nil {
+		return &UnsupportedParameterError{Parameter: "presence_penalty", Model: model.ID}
+	}
+	if opts.frequencyPenalty != nil {
+		return &UnsupportedParameterError{Parameter: "frequency_penalty", Model: model.ID}
+	}
+	if opts.logProbs != nil {
+		return &UnsupportedParameterError{Parameter: "logprobs", Model: model.ID}
+	}
+	if len(opts.stop) > 0 {
+		return &UnsupportedParameterError{Parameter: "stop", Model: model.ID}
+	}
+	if opts.n != nil && *opts.n != 1 {
+		return &UnsupportedParameterError{Parameter: "n", Model: model.ID}
+	}
+	if opts.reasoningEffort != "" {
+		params.ReasoningEffort = openai.ReasoningEffort(opts.reasoningEffort)
+	}
+	return nil
+}
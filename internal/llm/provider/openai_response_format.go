@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/crush/internal/llm/provider/jsonschema"
+	"github.com/openai/openai-go"
+)
+
+// resolveResponseFormat returns the ResponseFormat that should apply to
+// this call: the per-call override from ctx (see WithResponseFormat) if
+// present, otherwise the client's configured default. A json_schema
+// format with Strict set is validated against OpenAI's strict-mode
+// requirements before it reaches the wire.
+func (c *openaiClient) resolveResponseFormat(ctx context.Context) (ResponseFormat, error) {
+	format := c.providerOptions.responseFormat
+	if override, ok := responseFormatFromContext(ctx); ok {
+		format = override
+	}
+
+	if format.Type == ResponseFormatJSONSchema {
+		if format.JSONSchema == nil {
+			return ResponseFormat{}, fmt.Errorf("response format: json_schema requires a JSONSchema definition")
+		}
+		if format.JSONSchema.Strict {
+			if err := jsonschema.ValidateStrict(format.JSONSchema.Schema); err != nil {
+				return ResponseFormat{}, fmt.Errorf("response format: %w", err)
+			}
+		}
+	}
+
+	return format, nil
+}
+
+// applyResponseFormat sets params.ResponseFormat from format, translating
+// our provider-agnostic ResponseFormat into the OpenAI SDK's
+// response_format union. A zero-value (or explicit "text") format is left
+// as the API default.
+func applyResponseFormat(params *openai.ChatCompletionNewParams, format ResponseFormat) {
+	switch format.Type {
+	case ResponseFormatJSONObject:
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONObject: &openai.ResponseFormatJSONObjectParam{},
+		}
+	case ResponseFormatJSONSchema:
+		js := format.JSONSchema
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+				JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:        js.Name,
+					Description: openai.String(js.Description),
+					Schema:      js.Schema,
+					Strict:      openai.Bool(js.Strict),
+				},
+			},
+		}
+	}
+}
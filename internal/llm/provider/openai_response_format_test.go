@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/llm/provider/jsonschema"
+	"github.com/openai/openai-go"
+)
+
+func TestResolveResponseFormat(t *testing.T) {
+	validSchema := jsonschema.Definition{
+		Type:                 jsonschema.Object,
+		Properties:           map[string]jsonschema.Definition{"answer": {Type: jsonschema.String}},
+		Required:             []string{"answer"},
+		AdditionalProperties: false,
+	}
+
+	t.Run("default from provider options", func(t *testing.T) {
+		client := &openaiClient{
+			providerOptions: providerClientOptions{
+				responseFormat: ResponseFormat{Type: ResponseFormatJSONObject},
+			},
+		}
+
+		format, err := client.resolveResponseFormat(t.Context())
+		if err != nil {
+			t.Fatalf("resolveResponseFormat() error = %v", err)
+		}
+		if format.Type != ResponseFormatJSONObject {
+			t.Errorf("format.Type = %v, want %v", format.Type, ResponseFormatJSONObject)
+		}
+	})
+
+	t.Run("per-call override wins", func(t *testing.T) {
+		client := &openaiClient{
+			providerOptions: providerClientOptions{
+				responseFormat: ResponseFormat{Type: ResponseFormatText},
+			},
+		}
+
+		ctx := WithResponseFormat(t.Context(), ResponseFormat{
+			Type: ResponseFormatJSONSchema,
+			JSONSchema: &JSONSchemaResponseFormat{
+				Name:   "answer",
+				Strict: true,
+				Schema: validSchema,
+			},
+		})
+
+		format, err := client.resolveResponseFormat(ctx)
+		if err != nil {
+			t.Fatalf("resolveResponseFormat() error = %v", err)
+		}
+		if format.Type != ResponseFormatJSONSchema {
+			t.Errorf("format.Type = %v, want %v", format.Type, ResponseFormatJSONSchema)
+		}
+	})
+
+	t.Run("strict schema missing additionalProperties:false is rejected", func(t *testing.T) {
+		client := &openaiClient{}
+		ctx := WithResponseFormat(t.Context(), ResponseFormat{
+			Type: ResponseFormatJSONSchema,
+			JSONSchema: &JSONSchemaResponseFormat{
+				Name:   "answer",
+				Strict: true,
+				Schema: jsonschema.Definition{
+					Type:       jsonschema.Object,
+					Properties: map[string]jsonschema.Definition{"answer": {Type: jsonschema.String}},
+					Required:   []string{"answer"},
+					// AdditionalProperties intentionally left unset.
+				},
+			},
+		})
+
+		if _, err := client.resolveResponseFormat(ctx); err == nil {
+			t.Error("resolveResponseFormat() error = nil, want error for non-strict-compatible schema")
+		}
+	})
+
+	t.Run("json_schema without schema is rejected", func(t *testing.T) {
+		client := &openaiClient{}
+		ctx := WithResponseFormat(t.Context(), ResponseFormat{Type: ResponseFormatJSONSchema})
+
+		if _, err := client.resolveResponseFormat(ctx); err == nil {
+			t.Error("resolveResponseFormat() error = nil, want error when JSONSchema is nil")
+		}
+	})
+}
+
+func TestApplyResponseFormat(t *testing.T) {
+	t.Run("text leaves params untouched", func(t *testing.T) {
+		params := openai.ChatCompletionNewParams{}
+		applyResponseFormat(&params, ResponseFormat{Type: ResponseFormatText})
+
+		if params.ResponseFormat.OfJSONObject != nil || params.ResponseFormat.OfJSONSchema != nil {
+			t.Errorf("expected no response format to be set, got %+v", params.ResponseFormat)
+		}
+	})
+
+	t.Run("json_object sets the object variant", func(t *testing.T) {
+		params := openai.ChatCompletionNewParams{}
+		applyResponseFormat(&params, ResponseFormat{Type: ResponseFormatJSONObject})
+
+		if params.ResponseFormat.OfJSONObject == nil {
+			t.Error("expected OfJSONObject to be set")
+		}
+	})
+
+	t.Run("json_schema sets the schema variant", func(t *testing.T) {
+		params := openai.ChatCompletionNewParams{}
+		applyResponseFormat(&params, ResponseFormat{
+			Type: ResponseFormatJSONSchema,
+			JSONSchema: &JSONSchemaResponseFormat{
+				Name:   "answer",
+				Strict: true,
+				Schema: jsonschema.Definition{Type: jsonschema.Object},
+			},
+		})
+
+		if params.ResponseFormat.OfJSONSchema == nil {
+			t.Fatal("expected OfJSONSchema to be set")
+		}
+		if params.ResponseFormat.OfJSONSchema.JSONSchema.Name != "answer" {
+			t.Errorf("schema name = %q, want %q", params.ResponseFormat.OfJSONSchema.JSONSchema.Name, "answer")
+		}
+	})
+}
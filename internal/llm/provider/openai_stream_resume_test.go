@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/message"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// sseChunk renders a single chat.completion.chunk SSE "data:" line.
+func sseChunk(id, content string) string {
+	chunk := map[string]any{
+		"id":      id,
+		"object":  "chat.completion.chunk",
+		"created": time.Now().Unix(),
+		"model":   "test-model",
+		"choices": []any{
+			map[string]any{
+				"index": 0,
+				"delta": map[string]any{"content": content},
+			},
+		},
+	}
+	data, _ := json.Marshal(chunk)
+	return "data: " + string(data) + "\n\n"
+}
+
+// writeRawChunk writes payload as one HTTP chunked-transfer-encoding
+// frame directly to the hijacked connection.
+func writeRawChunk(w *bufio.ReadWriter, payload string) {
+	fmt.Fprintf(w, "%x\r\n%s\r\n", len(payload), payload)
+	w.Flush()
+}
+
+func TestOpenAIStreamResumesAfterMidStreamDisconnect(t *testing.T) {
+	var requestBodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body strings.Builder
+		buf := make([]byte, 4096)
+		n, _ := r.Body.Read(buf)
+		body.Write(buf[:n])
+		requestBodies = append(requestBodies, body.String())
+
+		if len(requestBodies) == 1 {
+			// Simulate a connection drop mid-stream: write one valid
+			// chunk over the raw connection, then close without sending
+			// the terminating 0-length chunk.
+			hj, ok := w.(http.Hijacker)
+			if !ok {
+				t.Error("ResponseWriter does not support hijacking")
+				return
+			}
+			conn, rw, err := hj.Hijack()
+			if err != nil {
+				t.Errorf("hijack failed: %v", err)
+				return
+			}
+			defer conn.Close()
+
+			rw.WriteString("HTTP/1.1 200 OK\r\n")
+			rw.WriteString("Content-Type: text/event-stream\r\n")
+			rw.WriteString("Transfer-Encoding: chunked\r\n\r\n")
+			writeRawChunk(rw, sseChunk("chunk-1", "Hello, "))
+			return
+		}
+
+		// The resumed request: finish the response normally. The first
+		// word is re-sent (as a real reconnect might) to exercise
+		// overlap de-duplication.
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(sseChunk("chunk-2", "Hello, world!")))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client := &openaiClient{
+		providerOptions: providerClientOptions{
+			modelType:          config.SelectedModelTypeLarge,
+			apiKey:             "test-key",
+			systemMessage:      "test",
+			streamRetryBackoff: time.Millisecond,
+			model: func(config.SelectedModelType) catwalk.Model {
+				return catwalk.Model{ID: "test-model", Name: "test-model"}
+			},
+		},
+		client: openai.NewClient(
+			option.WithAPIKey("test-key"),
+			option.WithBaseURL(server.URL),
+		),
+	}
+
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "Hi"}}},
+	}
+
+	var deltas strings.Builder
+	var complete *ProviderResponse
+	var gotErr error
+
+	for event := range client.stream(t.Context(), messages, nil) {
+		switch event.Type {
+		case EventContentDelta:
+			deltas.WriteString(event.Content)
+		case EventComplete:
+			complete = event.Response
+		case EventError:
+			gotErr = event.Error
+		}
+	}
+
+	if gotErr != nil {
+		t.Fatalf("stream() returned an error, want transparent resumption: %v", gotErr)
+	}
+	if complete == nil {
+		t.Fatal("expected an EventComplete event")
+	}
+	if want := "Hello, world!"; complete.Content != want || deltas.String() != want {
+		t.Errorf("final content = %q (deltas: %q), want %q", complete.Content, deltas.String(), want)
+	}
+
+	if len(requestBodies) != 2 {
+		t.Fatalf("expected 2 requests (original + resumed), got %d", len(requestBodies))
+	}
+	if !strings.Contains(requestBodies[1], `"role":"assistant"`) || !strings.Contains(requestBodies[1], "Hello, ") {
+		t.Error("resumed request did not replay the partial content as an assistant message")
+	}
+	if !strings.Contains(requestBodies[1], "Continue.") {
+		t.Error("resumed request did not include the continuation instruction")
+	}
+}
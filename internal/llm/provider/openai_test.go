@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -148,12 +149,19 @@ func TestIsAzureOpenAI(t *testing.T) {
 }
 
 func TestPreparedParamsProviderAware(t *testing.T) {
+	float := func(v float64) *float64 { return &v }
+	boolPtr := func(v bool) *bool { return &v }
+	intPtr := func(v int64) *int64 { return &v }
+
 	tests := []struct {
 		name                      string
 		baseURL                   string
 		modelCanReason            bool
+		opts                      providerClientOptions
 		expectMaxTokens           bool
 		expectMaxCompletionTokens bool
+		expectErrParam            string
+		expectReasoningEffort     ReasoningEffort
 	}{
 		{
 			name:                      "Azure OpenAI with non-reasoning model",
@@ -190,31 +198,115 @@ func TestPreparedParamsProviderAware(t *testing.T) {
 			expectMaxTokens:           false,
 			expectMaxCompletionTokens: true,
 		},
+		{
+			name:           "Standard OpenAI reasoning model rejects temperature",
+			baseURL:        "https://api.openai.com/v1",
+			modelCanReason: true,
+			opts:           providerClientOptions{temperature: float(0.7)},
+			expectErrParam: "temperature",
+		},
+		{
+			name:           "Standard OpenAI reasoning model rejects top_p",
+			baseURL:        "https://api.openai.com/v1",
+			modelCanReason: true,
+			opts:           providerClientOptions{topP: float(0.9)},
+			expectErrParam: "top_p",
+		},
+		{
+			name:           "Standard OpenAI reasoning model rejects presence_penalty",
+			baseURL:        "https://api.openai.com/v1",
+			modelCanReason: true,
+			opts:           providerClientOptions{presencePenalty: float(0.5)},
+			expectErrParam: "presence_penalty",
+		},
+		{
+			name:           "Standard OpenAI reasoning model rejects frequency_penalty",
+			baseURL:        "https://api.openai.com/v1",
+			modelCanReason: true,
+			opts:           providerClientOptions{frequencyPenalty: float(0.5)},
+			expectErrParam: "frequency_penalty",
+		},
+		{
+			name:           "Standard OpenAI reasoning model rejects logprobs",
+			baseURL:        "https://api.openai.com/v1",
+			modelCanReason: true,
+			opts:           providerClientOptions{logProbs: boolPtr(true)},
+			expectErrParam: "logprobs",
+		},
+		{
+			name:           "Standard OpenAI reasoning model rejects stop",
+			baseURL:        "https://api.openai.com/v1",
+			modelCanReason: true,
+			opts:           providerClientOptions{stop: []string{"\\n"}},
+			expectErrParam: "stop",
+		},
+		{
+			name:           "Standard OpenAI reasoning model rejects n != 1",
+			baseURL:        "https://api.openai.com/v1",
+			modelCanReason: true,
+			opts:           providerClientOptions{n: intPtr(2)},
+			expectErrParam: "n",
+		},
+		{
+			name:                      "Azure OpenAI reasoning model rejects temperature",
+			baseURL:                   "https://test.openai.azure.com",
+			modelCanReason:            true,
+			opts:                      providerClientOptions{temperature: float(0.7)},
+			expectErrParam:            "temperature",
+			expectMaxCompletionTokens: false,
+		},
+		{
+			name:                      "Standard OpenAI reasoning model accepts n == 1",
+			baseURL:                   "https://api.openai.com/v1",
+			modelCanReason:            true,
+			opts:                      providerClientOptions{n: intPtr(1)},
+			expectMaxCompletionTokens: true,
+		},
+		{
+			name:                      "Standard OpenAI reasoning model plumbs reasoning effort",
+			baseURL:                   "https://api.openai.com/v1",
+			modelCanReason:            true,
+			opts:                      providerClientOptions{reasoningEffort: ReasoningEffortHigh},
+			expectMaxCompletionTokens: true,
+			expectReasoningEffort:     ReasoningEffortHigh,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			client := &openaiClient{
-				providerOptions: providerClientOptions{
-					baseURL:   tt.baseURL,
-					modelType: config.SelectedModelTypeLarge,
-					model: func(config.SelectedModelType) catwalk.Model {
-						return catwalk.Model{
-							ID:               "test-model",
-							Name:             "test-model",
-							DefaultMaxTokens: 1000,
-							CanReason:        tt.modelCanReason,
-						}
-					},
-				},
+			opts := tt.opts
+			opts.baseURL = tt.baseURL
+			opts.modelType = config.SelectedModelTypeLarge
+			opts.model = func(config.SelectedModelType) catwalk.Model {
+				return catwalk.Model{
+					ID:               "test-model",
+					Name:             "test-model",
+					DefaultMaxTokens: 1000,
+					CanReason:        tt.modelCanReason,
+				}
 			}
+			client := &openaiClient{providerOptions: opts}
 
 			messages := []openai.ChatCompletionMessageParamUnion{
 				openai.UserMessage("test message"),
 			}
 			tools := []openai.ChatCompletionToolParam{}
 
-			params := client.preparedParams(messages, tools)
+			params, err := client.preparedParams(messages, tools)
+
+			if tt.expectErrParam != "" {
+				var unsupported *UnsupportedParameterError
+				if err == nil || !errors.As(err, &unsupported) {
+					t.Fatalf("expected UnsupportedParameterError for %q, got %v", tt.expectErrParam, err)
+				}
+				if unsupported.Parameter != tt.expectErrParam {
+					t.Errorf("UnsupportedParameterError.Parameter = %q, want %q", unsupported.Parameter, tt.expectErrParam)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("preparedParams() unexpected error: %v", err)
+			}
 
 			// Check MaxTokens
 			if tt.expectMaxTokens {
@@ -241,6 +333,27 @@ func TestPreparedParamsProviderAware(t *testing.T) {
 					t.Errorf("Expected MaxCompletionTokens to be unset, but it was set to %d", params.MaxCompletionTokens.Value)
 				}
 			}
+
+			if tt.expectReasoningEffort != "" && string(params.ReasoningEffort) != string(tt.expectReasoningEffort) {
+				t.Errorf("ReasoningEffort = %q, want %q", params.ReasoningEffort, tt.expectReasoningEffort)
+			}
 		})
 	}
 }
+
+func TestConvertMessagesSystemPromptForReasoningModels(t *testing.T) {
+	client := &openaiClient{
+		providerOptions: providerClientOptions{systemMessage: "you are a helpful assistant"},
+	}
+
+	converted := client.convertMessages(nil, true)
+	if len(converted) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(converted))
+	}
+	if converted[0].OfSystem != nil {
+		t.Error("expected the system prompt to be converted to a user message for a reasoning model")
+	}
+	if converted[0].OfUser == nil {
+		t.Error("expected the system prompt to be sent as a leading user message")
+	}
+}
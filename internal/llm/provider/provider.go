@@ -0,0 +1,182 @@
+// Package provider implements the LLM provider client used by crush to
+// talk to OpenAI and OpenAI-compatible backends (e.g. Azure OpenAI), plus
+// the ResponseFormat translations for Anthropic and Gemini.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/catwalk/pkg/catwalk"
+	"github.com/charmbracelet/crush/internal/config"
+	"github.com/charmbracelet/crush/internal/llm/provider/jsonschema"
+	"github.com/charmbracelet/crush/internal/message"
+)
+
+// EventType identifies the kind of event emitted while streaming a
+// response from a provider.
+type EventType string
+
+const (
+	EventContentStart  EventType = "content_start"
+	EventContentDelta  EventType = "content_delta"
+	EventThinkingDelta EventType = "thinking_delta"
+	EventToolUseStart  EventType = "tool_use_start"
+	EventToolUseDelta  EventType = "tool_use_delta"
+	EventToolUseStop   EventType = "tool_use_stop"
+	EventComplete      EventType = "complete"
+	EventError         EventType = "error"
+	EventRateLimit     EventType = "rate_limit"
+)
+
+// ProviderEvent is a single item of a streamed provider response. Only the
+// fields relevant to Type are populated.
+type ProviderEvent struct {
+	Type EventType
+
+	Content   string
+	Thinking  string
+	ToolCall  *message.ToolCall
+	Response  *ProviderResponse
+	RateLimit *RateLimitInfo
+	Error     error
+}
+
+// TokenUsage reports how many tokens a completion consumed.
+type TokenUsage struct {
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+}
+
+// ProviderResponse is the final, assembled result of a non-streaming (or
+// fully drained streaming) completion.
+type ProviderResponse struct {
+	Content      string
+	ToolCalls    []message.ToolCall
+	Usage        TokenUsage
+	FinishReason message.FinishReason
+}
+
+// ProviderClient is implemented by every model backend crush can talk to.
+type ProviderClient interface {
+	// send performs a single, non-streaming completion request.
+	send(ctx context.Context, messages []message.Message, tools []ToolDefinition) (*ProviderResponse, error)
+	// stream performs a streaming completion request, emitting events as
+	// the response is produced.
+	stream(ctx context.Context, messages []message.Message, tools []ToolDefinition) <-chan ProviderEvent
+}
+
+// ToolDefinition describes a tool the model is allowed to call.
+type ToolDefinition struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+}
+
+// providerClientOptions carries the configuration shared by every
+// provider client implementation.
+type providerClientOptions struct {
+	baseURL       string
+	apiKey        string
+	systemMessage string
+	modelType     config.SelectedModelType
+	model         func(config.SelectedModelType) catwalk.Model
+	maxTokens     int64
+	extraHeaders  map[string]string
+	extraBody     map[string]any
+	azureAuth     *AzureAuthConfig
+	// rateLimitTokenThreshold is the remaining-token floor below which the
+	// client proactively waits out the reset window before issuing its
+	// next request. Defaults to defaultRateLimitTokenThreshold.
+	rateLimitTokenThreshold int64
+	// responseFormat is the default structured-output mode for this
+	// client; it can be overridden per call via WithResponseFormat.
+	responseFormat ResponseFormat
+
+	// Generation parameters. Reasoning (o1/o3 family) models reject all
+	// of these except reasoningEffort and an n of 1; see
+	// openaiClient.preparedParams.
+	temperature      *float64
+	topP             *float64
+	presencePenalty  *float64
+	frequencyPenalty *float64
+	logProbs         *bool
+	stop             []string
+	n                *int64
+	reasoningEffort  ReasoningEffort
+
+	// maxStreamRetries is how many times stream() will transparently
+	// reissue the request after a resumable mid-stream transport error.
+	// Defaults to defaultMaxStreamRetries.
+	maxStreamRetries int
+	// streamRetryBackoff is how long stream() waits before each
+	// resumption attempt. Defaults to defaultStreamRetryBackoff.
+	streamRetryBackoff time.Duration
+}
+
+// ReasoningEffort is OpenAI's `reasoning_effort` parameter for o1/o3-family
+// models, trading latency for answer quality.
+type ReasoningEffort string
+
+const (
+	ReasoningEffortLow    ReasoningEffort = "low"
+	ReasoningEffortMedium ReasoningEffort = "medium"
+	ReasoningEffortHigh   ReasoningEffort = "high"
+)
+
+// UnsupportedParameterError is returned when a caller configures a
+// generation parameter that the target model does not support, e.g. a
+// sampling parameter on a reasoning (o1/o3 family) model.
+type UnsupportedParameterError struct {
+	Parameter string
+	Model     string
+}
+
+func (e *UnsupportedParameterError) Error() string {
+	return fmt.Sprintf("parameter %q is not supported by model %q", e.Parameter, e.Model)
+}
+
+// ResponseFormatType selects how a provider should constrain its output.
+type ResponseFormatType string
+
+const (
+	ResponseFormatText       ResponseFormatType = "text"
+	ResponseFormatJSONObject ResponseFormatType = "json_object"
+	ResponseFormatJSONSchema ResponseFormatType = "json_schema"
+)
+
+// JSONSchemaResponseFormat describes a named, optionally strict JSON
+// schema the model's output must conform to.
+type JSONSchemaResponseFormat struct {
+	Name        string
+	Description string
+	Strict      bool
+	Schema      jsonschema.Definition
+}
+
+// ResponseFormat configures structured output for a request. It is
+// translated into each provider's native mechanism: OpenAI's
+// `response_format` (applyResponseFormat), Anthropic's tool-forcing
+// (AnthropicResponseFormat), or Gemini's JSON mode
+// (GeminiResponseFormatParam).
+type ResponseFormat struct {
+	Type ResponseFormatType
+	// JSONSchema is set when Type is ResponseFormatJSONSchema.
+	JSONSchema *JSONSchemaResponseFormat
+}
+
+type responseFormatCtxKey struct{}
+
+// WithResponseFormat overrides the client's default ResponseFormat for a
+// single call.
+func WithResponseFormat(ctx context.Context, format ResponseFormat) context.Context {
+	return context.WithValue(ctx, responseFormatCtxKey{}, format)
+}
+
+func responseFormatFromContext(ctx context.Context) (ResponseFormat, bool) {
+	format, ok := ctx.Value(responseFormatCtxKey{}).(ResponseFormat)
+	return format, ok
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/crush/internal/llm/provider/jsonschema"
+	"github.com/openai/openai-go"
+)
+
+// TestResponseFormatProviderAware exercises the translation functions
+// directly, one ResponseFormat translated three ways. Unlike
+// TestPreparedParamsProviderAware, there is no Anthropic or Gemini provider
+// client in this package yet to drive end-to-end, so this only covers
+// AnthropicResponseFormat/GeminiResponseFormatParam in isolation; it
+// should be extended to go through those clients' real request-building
+// code once they exist.
+func TestResponseFormatProviderAware(t *testing.T) {
+	schema := jsonschema.Definition{
+		Type:       jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{"answer": {Type: jsonschema.String}},
+		Required:   []string{"answer"},
+	}
+
+	tests := []struct {
+		name   string
+		format ResponseFormat
+	}{
+		{name: "text", format: ResponseFormat{Type: ResponseFormatText}},
+		{name: "json_object", format: ResponseFormat{Type: ResponseFormatJSONObject}},
+		{
+			name: "json_schema",
+			format: ResponseFormat{
+				Type:       ResponseFormatJSONSchema,
+				JSONSchema: &JSONSchemaResponseFormat{Name: "answer", Schema: schema},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/openai", func(t *testing.T) {
+			params := openai.ChatCompletionNewParams{}
+			applyResponseFormat(&params, tt.format)
+
+			wantSet := tt.format.Type != ResponseFormatText
+			gotSet := params.ResponseFormat.OfJSONObject != nil || params.ResponseFormat.OfJSONSchema != nil
+			if gotSet != wantSet {
+				t.Errorf("response_format set = %v, want %v", gotSet, wantSet)
+			}
+		})
+
+		t.Run(tt.name+"/anthropic", func(t *testing.T) {
+			choice, ok := AnthropicResponseFormat(tt.format)
+
+			wantOK := tt.format.Type != ResponseFormatText
+			if ok != wantOK {
+				t.Errorf("AnthropicResponseFormat() ok = %v, want %v", ok, wantOK)
+			}
+			if ok && choice.ToolName != AnthropicStructuredOutputTool {
+				t.Errorf("ToolName = %q, want %q", choice.ToolName, AnthropicStructuredOutputTool)
+			}
+			if tt.format.Type == ResponseFormatJSONSchema && choice.InputSchema.Type != jsonschema.Object {
+				t.Errorf("InputSchema.Type = %v, want %v", choice.InputSchema.Type, jsonschema.Object)
+			}
+		})
+
+		t.Run(tt.name+"/gemini", func(t *testing.T) {
+			got := GeminiResponseFormatParam(tt.format)
+
+			wantMIME := ""
+			if tt.format.Type != ResponseFormatText {
+				wantMIME = "application/json"
+			}
+			if got.ResponseMIMEType != wantMIME {
+				t.Errorf("ResponseMIMEType = %q, want %q", got.ResponseMIMEType, wantMIME)
+			}
+
+			wantSchema := tt.format.Type == ResponseFormatJSONSchema
+			if (got.ResponseSchema != nil) != wantSchema {
+				t.Errorf("ResponseSchema set = %v, want %v", got.ResponseSchema != nil, wantSchema)
+			}
+		})
+	}
+}
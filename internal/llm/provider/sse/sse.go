@@ -0,0 +1,93 @@
+// Package sse holds the resumption bookkeeping for chat-completion style
+// Server-Sent-Events streams. It does not decode SSE itself; it tracks how
+// much of the response has already been delivered so that a caller can
+// transparently reissue the underlying request after a transient
+// transport error and splice the continuation back into one logical
+// stream.
+package sse
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+)
+
+// Chunk is a single delta from a chat-completion style SSE stream.
+type Chunk struct {
+	// ID is the upstream chunk/event ID, when the server sends one. Used
+	// only for diagnostics; resumption is driven by accumulated Content.
+	ID      string
+	Content string
+	Done    bool
+}
+
+// State accumulates the assistant text delivered so far, so that a
+// reconnect can pick up where the stream left off.
+type State struct {
+	LastChunkID string
+	content     strings.Builder
+}
+
+// Content returns everything accumulated so far.
+func (s *State) Content() string {
+	return s.content.String()
+}
+
+// Observe folds chunk into the accumulated state, trimming any overlap
+// with what was already delivered (see MergeOverlap), and returns the
+// non-duplicate delta that should actually be forwarded to the caller.
+func (s *State) Observe(chunk Chunk) string {
+	if chunk.ID != "" {
+		s.LastChunkID = chunk.ID
+	}
+	delta := MergeOverlap(s.content.String(), chunk.Content)
+	s.content.WriteString(delta)
+	return delta
+}
+
+// MergeOverlap returns the suffix of incoming that is not already covered
+// by the tail of existing. Reconnecting mid-stream can cause the server
+// to re-send a few tokens the client already has (since the "continue
+// from here" instruction isn't pixel-precise); this trims that overlap so
+// the merged content doesn't repeat tokens.
+func MergeOverlap(existing, incoming string) string {
+	max := len(incoming)
+	if len(existing) < max {
+		max = len(existing)
+	}
+	for l := max; l > 0; l-- {
+		if strings.HasSuffix(existing, incoming[:l]) {
+			return incoming[l:]
+		}
+	}
+	return incoming
+}
+
+// IsResumable reports whether err represents a transient mid-stream
+// transport failure that a client should recover from by reissuing the
+// request, rather than a permanent/terminal error.
+func IsResumable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	// HTTP/2 surfaces a mid-stream reset from the peer as a StreamError
+	// whose string contains its error code name; golang.org/x/net/http2
+	// isn't worth vendoring just to type-assert this.
+	if strings.Contains(err.Error(), "INTERNAL_ERROR") {
+		return true
+	}
+	return false
+}
+
+// ContinueInstruction is the short nudge sent as a fresh user message when
+// resuming a stream. The partial content itself is replayed as a real
+// assistant message ahead of it, so the model sees an ordinary turn to
+// continue rather than an instruction describing its own cut-off answer.
+const ContinueInstruction = "Continue."
@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestMergeOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		incoming string
+		want     string
+	}{
+		{name: "no overlap", existing: "hello ", incoming: "world", want: "world"},
+		{name: "full overlap", existing: "hello world", incoming: "hello world", want: ""},
+		{name: "partial overlap", existing: "the quick brown", incoming: "brown fox", want: " fox"},
+		{name: "empty existing", existing: "", incoming: "hello", want: "hello"},
+		{name: "empty incoming", existing: "hello", incoming: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeOverlap(tt.existing, tt.incoming); got != tt.want {
+				t.Errorf("MergeOverlap(%q, %q) = %q, want %q", tt.existing, tt.incoming, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateObserve(t *testing.T) {
+	var s State
+
+	if delta := s.Observe(Chunk{ID: "1", Content: "the quick "}); delta != "the quick " {
+		t.Errorf("first Observe() = %q, want %q", delta, "the quick ")
+	}
+	if delta := s.Observe(Chunk{ID: "2", Content: "brown fox"}); delta != "brown fox" {
+		t.Errorf("second Observe() = %q, want %q", delta, "brown fox")
+	}
+
+	// A reconnect re-sends the tail of what was already delivered.
+	if delta := s.Observe(Chunk{ID: "3", Content: "brown fox jumps"}); delta != " jumps" {
+		t.Errorf("overlapping Observe() = %q, want %q", delta, " jumps")
+	}
+
+	if got, want := s.Content(), "the quick brown fox jumps"; got != want {
+		t.Errorf("Content() = %q, want %q", got, want)
+	}
+	if s.LastChunkID != "3" {
+		t.Errorf("LastChunkID = %q, want %q", s.LastChunkID, "3")
+	}
+}
+
+func TestIsResumable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, want: true},
+		{name: "unexpected EOF text, not wrapped", err: errors.New("read tcp: " + io.ErrUnexpectedEOF.Error()), want: false},
+		{name: "http2 internal error", err: errors.New(`stream error: stream ID 1; INTERNAL_ERROR`), want: true},
+		{name: "context canceled", err: context.Canceled, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsResumable(tt.err); got != tt.want {
+				t.Errorf("IsResumable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}